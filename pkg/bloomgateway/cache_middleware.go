@@ -0,0 +1,133 @@
+package bloomgateway
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/user"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache/resultscache"
+)
+
+// BloomGatewayClientCacheMiddleware wraps a logproto.BloomGatewayClient and
+// caches FilterChunkRefs responses client-side, keyed by tenant, queried
+// block list and query plan. A request with CachingOptions.Disabled set
+// mirrors the `Cache-Control: no-cache` behavior used elsewhere in the query
+// path: the cache lookup is skipped and the request always reaches the
+// server, but the response is still written back to the cache so that
+// subsequent, cache-enabled callers benefit from it.
+//
+// All other RPCs (e.g. FilterChunkRefsStream) are passed straight through to
+// the wrapped client, since streamed responses aren't cached.
+type BloomGatewayClientCacheMiddleware struct {
+	logproto.BloomGatewayClient
+
+	logger           log.Logger
+	cache            cache.Cache
+	limits           Limits
+	cacheGen         resultscache.CacheGenNumberLoader
+	retentionEnabled bool
+}
+
+// NewBloomGatewayClientCacheMiddleware wraps next with a client-side results
+// cache backed by c.
+func NewBloomGatewayClientCacheMiddleware(
+	logger log.Logger,
+	next logproto.BloomGatewayClient,
+	c cache.Cache,
+	limits Limits,
+	cacheGen resultscache.CacheGenNumberLoader,
+	retentionEnabled bool,
+) *BloomGatewayClientCacheMiddleware {
+	return &BloomGatewayClientCacheMiddleware{
+		BloomGatewayClient: next,
+		logger:             logger,
+		cache:              c,
+		limits:             limits,
+		cacheGen:           cacheGen,
+		retentionEnabled:   retentionEnabled,
+	}
+}
+
+// FilterChunkRefs implements logproto.BloomGatewayClient.
+func (m *BloomGatewayClientCacheMiddleware) FilterChunkRefs(ctx context.Context, req *logproto.FilterChunkRefRequest, opts ...grpc.CallOption) (*logproto.FilterChunkRefResponse, error) {
+	tenantID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		// no tenant to scope the cache key to; don't cache this request.
+		return m.BloomGatewayClient.FilterChunkRefs(ctx, req, opts...)
+	}
+
+	key := m.cacheKey(tenantID, req)
+
+	if req.CachingOptions.Disabled {
+		level.Debug(m.logger).Log("msg", "bypassing bloom gateway client cache for request", "tenant", tenantID)
+	} else if resp, ok := m.fetch(ctx, key); ok {
+		return resp, nil
+	}
+
+	resp, err := m.BloomGatewayClient.FilterChunkRefs(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.store(ctx, key, resp)
+	return resp, nil
+}
+
+func (m *BloomGatewayClientCacheMiddleware) fetch(ctx context.Context, key string) (*logproto.FilterChunkRefResponse, bool) {
+	found, bufs, _, err := m.cache.Fetch(ctx, []string{key})
+	if err != nil {
+		level.Warn(m.logger).Log("msg", "failed to fetch bloom gateway response from cache", "err", err)
+		return nil, false
+	}
+	if len(found) == 0 {
+		return nil, false
+	}
+
+	resp := &logproto.FilterChunkRefResponse{}
+	if err := resp.Unmarshal(bufs[0]); err != nil {
+		level.Warn(m.logger).Log("msg", "failed to unmarshal cached bloom gateway response", "err", err)
+		return nil, false
+	}
+	return resp, true
+}
+
+func (m *BloomGatewayClientCacheMiddleware) store(ctx context.Context, key string, resp *logproto.FilterChunkRefResponse) {
+	buf, err := resp.Marshal()
+	if err != nil {
+		level.Warn(m.logger).Log("msg", "failed to marshal bloom gateway response for caching", "err", err)
+		return
+	}
+	if err := m.cache.Store(ctx, []string{key}, [][]byte{buf}); err != nil {
+		level.Warn(m.logger).Log("msg", "failed to store bloom gateway response in cache", "err", err)
+	}
+}
+
+// cacheKey derives a cache key from the parts of the request that determine
+// its result: the tenant, the query time range, the requested blocks, the
+// requested series and the query plan, plus the current results cache
+// generation number so that deletes/retention changes invalidate previously
+// cached entries.
+func (m *BloomGatewayClientCacheMiddleware) cacheKey(tenantID string, req *logproto.FilterChunkRefRequest) string {
+	gen := m.cacheGen.GetResultsCacheGenNumber([]string{tenantID})
+	return fmt.Sprintf("BG:%s:%d:%d:%d:%s:%s:%x", tenantID, req.From, req.Through, gen, strings.Join(req.Blocks, ","), req.Plan.String(), hashRefs(req.Refs))
+}
+
+// hashRefs folds the fingerprints of refs into a single uint64 using the same
+// FNV-1a hash jump hashing uses, so that two requests asking about different
+// series over the same blocks and time range don't collide on the same cache
+// key.
+func hashRefs(refs []*logproto.GroupedChunkRefs) uint64 {
+	h := hashNew()
+	for _, ref := range refs {
+		h = hashAdd(h, strconv.FormatUint(ref.Fingerprint, 10)+",")
+	}
+	return h
+}
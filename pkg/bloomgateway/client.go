@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 	"sort"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -75,6 +76,23 @@ type ClientConfig struct {
 
 	// Client sharding using DNS disvovery and jumphash
 	Addresses string `yaml:"addresses,omitempty"`
+
+	// ReplicationFactor is the number of bloom gateway replicas queried for each block.
+	// A value greater than 1 allows FilterChunks to hedge requests across replicas and
+	// to tolerate a single replica being unavailable (e.g. during a rollout) without
+	// falling back to returning unfiltered chunks.
+	ReplicationFactor int `yaml:"replication_factor,omitempty"`
+
+	// HedgeAfter is how long FilterChunks waits for the primary replica to respond
+	// before racing a second request against the next replica. Only takes effect
+	// when ReplicationFactor is greater than 1.
+	HedgeAfter time.Duration `yaml:"hedge_after,omitempty"`
+
+	// StreamingEnabled switches FilterChunks from the unary FilterChunkRefs RPC
+	// to the server-streaming FilterChunkRefsStream RPC, so the client consumes
+	// filtered chunk refs incrementally instead of materializing the entire
+	// result set in memory before merging.
+	StreamingEnabled bool `yaml:"streaming_enabled,omitempty"`
 }
 
 // RegisterFlags registers flags for the Bloom Gateway client configuration.
@@ -89,6 +107,9 @@ func (i *ClientConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	i.PoolConfig.RegisterFlagsWithPrefix(prefix+"pool.", f)
 	f.BoolVar(&i.CacheResults, prefix+"cache_results", false, "Flag to control whether to cache bloom gateway client requests/responses.")
 	f.StringVar(&i.Addresses, prefix+"addresses", "", "Comma separated addresses list in DNS Service Discovery format: https://grafana.com/docs/mimir/latest/configure/about-dns-service-discovery/#supported-discovery-modes")
+	f.IntVar(&i.ReplicationFactor, prefix+"replication-factor", 1, "Number of bloom gateway replicas to query for each block. Values greater than 1 enable hedged, replica-aware requests.")
+	f.DurationVar(&i.HedgeAfter, prefix+"hedge-after", 500*time.Millisecond, "Time to wait for the primary replica to respond before racing a request against the next replica. Only used when replication-factor is greater than 1.")
+	f.BoolVar(&i.StreamingEnabled, prefix+"streaming-enabled", false, "Flag to control whether to use the server-streaming FilterChunkRefsStream RPC instead of the unary FilterChunkRefs RPC.")
 }
 
 func (i *ClientConfig) Validate() error {
@@ -110,16 +131,33 @@ func (i *ClientConfig) Validate() error {
 		return errors.New("addresses requires a list of comma separated strings in DNS service discovery format with at least one item")
 	}
 
+	if i.ReplicationFactor < 1 {
+		return errors.New("replication-factor must be greater than or equal to 1")
+	}
+
 	return nil
 }
 
 type Client interface {
-	FilterChunks(ctx context.Context, tenant string, interval bloomshipper.Interval, blocks []blockWithSeries, plan plan.QueryPlan) ([]*logproto.GroupedChunkRefs, error)
+	FilterChunks(ctx context.Context, tenant string, interval bloomshipper.Interval, blocks []blockWithSeries, plan plan.QueryPlan, opts ...CachingOptions) ([]*logproto.GroupedChunkRefs, error)
+}
+
+// CachingOptions controls whether a single FilterChunks call is allowed to
+// answer from the bloom gateway client results cache.
+// It mirrors the `Cache-Control: no-cache` handling used elsewhere in the
+// query path: the request is still served and the response is still written
+// back to the cache, but the cache is not consulted for the lookup.
+type CachingOptions struct {
+	// Disabled, when true, bypasses the results cache lookup for this request,
+	// provided the tenant's limits allow disabling the cache; otherwise it is
+	// ignored.
+	Disabled bool
 }
 
 type GatewayClient struct {
 	cfg         ClientConfig
 	logger      log.Logger
+	limits      Limits
 	metrics     *clientMetrics
 	pool        *JumpHashClientPool
 	dnsProvider *discovery.DNS
@@ -190,6 +228,7 @@ func NewClient(
 	return &GatewayClient{
 		cfg:         cfg,
 		logger:      logger,
+		limits:      limits,
 		metrics:     metrics,
 		pool:        pool,
 		dnsProvider: dnsProvider, // keep reference so we can stop it when the client is closed
@@ -201,18 +240,42 @@ func (c *GatewayClient) Close() {
 	c.dnsProvider.Stop()
 }
 
+// replicationFactor returns the number of bloom gateway replicas to query per
+// block, defaulting to 1 (single replica, no hedging) for zero-value configs.
+func (c *GatewayClient) replicationFactor() int {
+	if c.cfg.ReplicationFactor < 1 {
+		return 1
+	}
+	return c.cfg.ReplicationFactor
+}
+
 // FilterChunkRefs implements Client
-func (c *GatewayClient) FilterChunks(ctx context.Context, _ string, interval bloomshipper.Interval, blocks []blockWithSeries, plan plan.QueryPlan) ([]*logproto.GroupedChunkRefs, error) {
+func (c *GatewayClient) FilterChunks(ctx context.Context, tenant string, interval bloomshipper.Interval, blocks []blockWithSeries, plan plan.QueryPlan, opts ...CachingOptions) ([]*logproto.GroupedChunkRefs, error) {
 	// no block and therefore no series with chunks
 	if len(blocks) == 0 {
 		return nil, nil
 	}
 
+	var cacheDisabled bool
+	for _, opt := range opts {
+		if opt.Disabled {
+			cacheDisabled = true
+		}
+	}
+	// Tenants can be restricted from disabling the cache, since an unbounded
+	// number of no-cache requests can be used to exhaust bloom gateway CPU.
+	if cacheDisabled && !c.limits.BloomGatewayCacheDisableAllowed(tenant) {
+		cacheDisabled = false
+	}
+	if cacheDisabled {
+		c.metrics.clientCacheBypassTotal.Inc()
+	}
+
 	firstFp, lastFp := uint64(math.MaxUint64), uint64(0)
 	pos := make(map[string]int)
 	servers := make([]addrWithGroups, 0, len(blocks))
 	for _, blockWithSeries := range blocks {
-		addr, err := c.pool.Addr(blockWithSeries.block.String())
+		addrs, err := c.pool.Addr(blockWithSeries.block.String(), c.replicationFactor())
 		if err != nil {
 			return nil, errors.Wrapf(err, "server address for block: %s", blockWithSeries.block)
 		}
@@ -226,21 +289,41 @@ func (c *GatewayClient) FilterChunks(ctx context.Context, _ string, interval blo
 			lastFp = last.Fingerprint
 		}
 
-		if idx, found := pos[addr]; found {
+		// the primary (first) address identifies ownership of the block for grouping purposes;
+		// the remaining addresses are only consulted for hedging/fallback.
+		primary := addrs[0]
+		if idx, found := pos[primary]; found {
 			servers[idx].groups = append(servers[idx].groups, blockWithSeries.series...)
 			servers[idx].blocks = append(servers[idx].blocks, blockWithSeries.block.String())
 		} else {
-			pos[addr] = len(servers)
+			pos[primary] = len(servers)
 			servers = append(servers, addrWithGroups{
-				addr:   addr,
+				addrs:  addrs,
 				blocks: []string{blockWithSeries.block.String()},
 				groups: blockWithSeries.series,
 			})
 		}
 	}
 
-	results := make([][]*logproto.GroupedChunkRefs, len(servers))
+	// cacheLocalityScore estimates how well jumphash distributed this request's
+	// keyspace across instances: it converges to 1 when querying X% of the
+	// keyspace needs X% of the instances, and drops toward 1-2/N when the
+	// request straddles instance boundaries.
+	if len(servers) > 0 {
+		numInstances := len(c.pool.Addrs())
+		if numInstances < 1 {
+			numInstances = 1
+		}
+		pctKeyspace := float64(lastFp-firstFp) / float64(math.MaxUint64)
+		pctInstances := float64(len(servers)) / float64(numInstances)
+		c.metrics.cacheLocalityScore.Observe(pctKeyspace / pctInstances)
+	}
+
+	iters := make([]v1.PeekingIterator[*logproto.GroupedChunkRefs], len(servers))
 	count := atomic.NewInt64(0)
+	// streams are read by mergeSeries after ForEachJob returns, at which point
+	// the per-job context it hands out is cancelled; open them on ours instead.
+	streamCtx := ctx
 	err := concurrency.ForEachJob(ctx, len(servers), len(servers), func(ctx context.Context, i int) error {
 		rs := servers[i]
 
@@ -248,36 +331,46 @@ func (c *GatewayClient) FilterChunks(ctx context.Context, _ string, interval blo
 			return rs.groups[i].Fingerprint < rs.groups[j].Fingerprint
 		})
 
-		return c.doForAddrs([]string{rs.addr}, func(client logproto.BloomGatewayClient) error {
-			req := &logproto.FilterChunkRefRequest{
-				From:    interval.Start,
-				Through: interval.End,
-				Refs:    rs.groups,
-				Blocks:  rs.blocks,
-				Plan:    plan,
-			}
-			resp, err := client.FilterChunkRefs(ctx, req)
-			if err != nil {
-				// We don't want a single bloom-gw failure to fail the entire query,
-				// so instrument & move on
-				level.Error(c.logger).Log(
-					"msg", "filter failed for instance, skipping",
-					"addr", rs.addr,
-					"series", len(rs.groups),
-					"blocks", len(rs.blocks),
-					"err", err,
-				)
-				// filter none of the results on failed request
-				c.metrics.clientRequests.WithLabelValues(typeError).Inc()
-				results[i] = rs.groups
-			} else {
-				c.metrics.clientRequests.WithLabelValues(typeSuccess).Inc()
-				results[i] = resp.ChunkRefs
-			}
+		req := &logproto.FilterChunkRefRequest{
+			From:           interval.Start,
+			Through:        interval.End,
+			Refs:           rs.groups,
+			Blocks:         rs.blocks,
+			Plan:           plan,
+			CachingOptions: logproto.CachingOptions{Disabled: cacheDisabled},
+		}
 
-			count.Add(int64(len(results[i])))
+		if c.cfg.StreamingEnabled {
+			iters[i] = c.filterChunkRefsStream(streamCtx, rs, req, count)
 			return nil
-		})
+		}
+
+		resp, _, err := c.filterChunkRefsHedged(ctx, rs.addrs, c.cfg.HedgeAfter, req)
+		var refs []*logproto.GroupedChunkRefs
+		if err != nil {
+			// We don't want a single bloom-gw failure to fail the entire query,
+			// so instrument & move on
+			level.Error(c.logger).Log(
+				"msg", "filter failed for all replicas, skipping",
+				"addrs", rs.addrs,
+				"series", len(rs.groups),
+				"blocks", len(rs.blocks),
+				"err", err,
+			)
+			// every replica failed: fall back to returning this server's chunks
+			// unfiltered rather than failing the whole query.
+			c.metrics.clientRequests.WithLabelValues(typeError).Inc()
+			c.metrics.clientReplicaFallbacksTotal.Inc()
+			refs = rs.groups
+		} else {
+			c.metrics.clientRequests.WithLabelValues(typeSuccess).Inc()
+			refs = resp.ChunkRefs
+			sort.Slice(refs, func(i, j int) bool { return refs[i].Fingerprint < refs[j].Fingerprint })
+		}
+
+		count.Add(int64(len(refs)))
+		iters[i] = v1.NewPeekingIter[*logproto.GroupedChunkRefs](v1.NewSliceIter(refs))
+		return nil
 	})
 
 	if err != nil {
@@ -285,25 +378,132 @@ func (c *GatewayClient) FilterChunks(ctx context.Context, _ string, interval blo
 	}
 
 	buf := make([]*logproto.GroupedChunkRefs, 0, int(count.Load()))
-	return mergeSeries(results, buf)
+	return mergeSeries(iters, buf)
+}
+
+// filterChunkRefsStream opens the server-streaming FilterChunkRefsStream RPC
+// against the first replica of rs reachable, trying addrs one after another
+// (unlike filterChunkRefsHedged, no requests are raced), and wraps the
+// response stream in a v1.PeekingIterator so callers can merge results
+// without waiting for (or materializing) the full response. If the stream
+// cannot be opened against any replica, the iterator replays rs.groups
+// unfiltered -- the same behavior the unary path uses on a hard RPC failure;
+// if it errors out once already in flight, the rest of rs.groups is replayed
+// unfiltered (see chunkRefStreamIterator).
+func (c *GatewayClient) filterChunkRefsStream(ctx context.Context, rs addrWithGroups, req *logproto.FilterChunkRefRequest, count *atomic.Int64) v1.PeekingIterator[*logproto.GroupedChunkRefs] {
+	var (
+		stream logproto.BloomGatewayClient_FilterChunkRefsStreamClient
+		addr   string
+		err    error
+	)
+	for _, a := range rs.addrs {
+		addr = a
+		err = c.doForAddrs([]string{addr}, func(client logproto.BloomGatewayClient) error {
+			var err error
+			stream, err = client.FilterChunkRefsStream(ctx, req)
+			return err
+		})
+		if err == nil {
+			break
+		}
+		level.Error(c.logger).Log(
+			"msg", "opening filter stream failed for instance, trying next replica",
+			"addr", addr,
+			"series", len(rs.groups),
+			"blocks", len(rs.blocks),
+			"err", err,
+		)
+	}
+	if err != nil {
+		c.metrics.clientRequests.WithLabelValues(typeError).Inc()
+		c.metrics.clientReplicaFallbacksTotal.Inc()
+		count.Add(int64(len(rs.groups)))
+		return v1.NewPeekingIter[*logproto.GroupedChunkRefs](v1.NewSliceIter(rs.groups))
+	}
+
+	c.metrics.clientRequests.WithLabelValues(typeSuccess).Inc()
+	return v1.NewPeekingIter[*logproto.GroupedChunkRefs](newChunkRefStreamIterator(c.logger, addr, stream.Recv, rs.groups, count))
+}
+
+// chunkRefStreamIterator adapts a FilterChunkRefsStream response stream into a
+// v1.Iterator, counting chunk ref groups as they are received. If the stream
+// errors out mid-flight, it falls back to replaying the original, unfiltered
+// groups for that server instead of propagating the error -- but only the
+// groups whose fingerprint comes after the last one the stream already
+// yielded, so the combined sequence stays ascending and duplicate-free for
+// mergeSeries' heap merge. fallback must be sorted ascending by fingerprint.
+type chunkRefStreamIterator struct {
+	logger log.Logger
+	addr   string
+	recv   func() (*logproto.GroupedChunkRefs, error)
+	count  *atomic.Int64
+
+	fallback    []*logproto.GroupedChunkRefs
+	fallbackIdx int
+	useFallback bool
+
+	lastFingerprint uint64
+	yielded         bool
+
+	cur *logproto.GroupedChunkRefs
 }
 
+func newChunkRefStreamIterator(logger log.Logger, addr string, recv func() (*logproto.GroupedChunkRefs, error), fallback []*logproto.GroupedChunkRefs, count *atomic.Int64) *chunkRefStreamIterator {
+	return &chunkRefStreamIterator{logger: logger, addr: addr, recv: recv, fallback: fallback, count: count}
+}
+
+func (it *chunkRefStreamIterator) Next() bool {
+	if it.useFallback {
+		if it.fallbackIdx >= len(it.fallback) {
+			return false
+		}
+		it.cur = it.fallback[it.fallbackIdx]
+		it.fallbackIdx++
+		it.count.Add(1)
+		return true
+	}
+
+	refs, err := it.recv()
+	if err == io.EOF {
+		return false
+	}
+	if err != nil {
+		level.Error(it.logger).Log("msg", "filter stream failed for instance, falling back to remaining unfiltered chunks", "addr", it.addr, "err", err)
+		it.useFallback = true
+		if it.yielded {
+			// skip fallback groups already superseded by fingerprints the
+			// stream successfully filtered before it failed.
+			it.fallbackIdx = sort.Search(len(it.fallback), func(i int) bool {
+				return it.fallback[i].Fingerprint > it.lastFingerprint
+			})
+		}
+		return it.Next()
+	}
+
+	it.cur = refs
+	it.lastFingerprint = refs.Fingerprint
+	it.yielded = true
+	it.count.Add(1)
+	return true
+}
+
+func (it *chunkRefStreamIterator) At() *logproto.GroupedChunkRefs { return it.cur }
+
+// Err always returns nil: stream errors are handled by falling back to
+// unfiltered chunks rather than surfacing an error to the caller.
+func (it *chunkRefStreamIterator) Err() error { return nil }
+
 // mergeSeries combines responses from multiple FilterChunkRefs calls and deduplicates
-// chunks from series that appear in multiple responses.
+// chunks from series that appear in multiple responses. Each input iterator must
+// already yield groups in ascending fingerprint order.
 // To avoid allocations, an optional slice can be passed as second argument.
-func mergeSeries(input [][]*logproto.GroupedChunkRefs, buf []*logproto.GroupedChunkRefs) ([]*logproto.GroupedChunkRefs, error) {
+func mergeSeries(input []v1.PeekingIterator[*logproto.GroupedChunkRefs], buf []*logproto.GroupedChunkRefs) ([]*logproto.GroupedChunkRefs, error) {
 	// clear provided buffer
 	buf = buf[:0]
 
-	iters := make([]v1.PeekingIterator[*logproto.GroupedChunkRefs], 0, len(input))
-	for _, inp := range input {
-		sort.Slice(inp, func(i, j int) bool { return inp[i].Fingerprint < inp[j].Fingerprint })
-		iters = append(iters, v1.NewPeekingIter(v1.NewSliceIter(inp)))
-	}
-
 	heapIter := v1.NewHeapIterator[*logproto.GroupedChunkRefs](
 		func(a, b *logproto.GroupedChunkRefs) bool { return a.Fingerprint < b.Fingerprint },
-		iters...,
+		input...,
 	)
 
 	dedupeIter := v1.NewDedupingIter[*logproto.GroupedChunkRefs, *logproto.GroupedChunkRefs](
@@ -366,6 +566,118 @@ func mergeChunkSets(s1, s2 []*logproto.ShortRef) (result []*logproto.ShortRef) {
 	return result
 }
 
+// filterChunkRefsHedged issues req against addrs[0] and, unless that is the
+// only replica, races a second, identical request against addrs[1] once
+// hedgeAfter elapses without a response. An error response is treated the
+// same as a timed-out one: the next untried replica is launched immediately
+// rather than waiting out the rest of the hedge window, so a replica that is
+// down (e.g. mid-rollout) is retried right away instead of only after
+// hedgeAfter. This repeats for as many replicas as addrs provides. Whichever
+// replica answers successfully first wins; any requests still in flight are
+// left to finish in the background. An error is only returned once every
+// replica in addrs has failed.
+//
+// hedgeAfter <= 0 is treated as "race all replicas immediately" rather than
+// "don't hedge": a zero or negative timer fires as soon as it is scheduled,
+// so the next replica launches right away instead of the fallback being
+// skipped entirely.
+func (c *GatewayClient) filterChunkRefsHedged(ctx context.Context, addrs []string, hedgeAfter time.Duration, req *logproto.FilterChunkRefRequest) (*logproto.FilterChunkRefResponse, string, error) {
+	return hedgedCall(addrs, hedgeAfter, c.metrics, func(addr string) (*logproto.FilterChunkRefResponse, error) {
+		return c.filterChunkRefs(ctx, addr, req)
+	})
+}
+
+// hedgedCall races call against addrs[0] and, unless that is the only
+// address, launches call against the next untried address once hedgeAfter
+// elapses without a response. It underlies filterChunkRefsHedged; factored
+// out so the racing/retry logic can be unit tested without a live bloom
+// gateway connection pool.
+func hedgedCall(addrs []string, hedgeAfter time.Duration, metrics *clientMetrics, call func(addr string) (*logproto.FilterChunkRefResponse, error)) (*logproto.FilterChunkRefResponse, string, error) {
+	if len(addrs) == 1 {
+		resp, err := call(addrs[0])
+		return resp, addrs[0], err
+	}
+
+	type hedgeResult struct {
+		addr string
+		resp *logproto.FilterChunkRefResponse
+		err  error
+	}
+
+	resCh := make(chan hedgeResult, len(addrs))
+	request := func(addr string) {
+		resp, err := call(addr)
+		resCh <- hedgeResult{addr: addr, resp: resp, err: err}
+	}
+
+	launchNext := func(next int) {
+		metrics.clientHedgeLaunchesTotal.Inc()
+		go request(addrs[next])
+	}
+
+	go request(addrs[0])
+
+	timer := time.NewTimer(hedgeAfter)
+	defer timer.Stop()
+
+	pending, next := 1, 1
+	var lastErr error
+	for pending > 0 {
+		if next >= len(addrs) {
+			res := <-resCh
+			pending--
+			if res.err == nil {
+				if res.addr != addrs[0] {
+					metrics.clientHedgeWinsTotal.Inc()
+				}
+				return res.resp, res.addr, nil
+			}
+			lastErr = res.err
+			continue
+		}
+
+		select {
+		case res := <-resCh:
+			pending--
+			if res.err == nil {
+				if res.addr != addrs[0] {
+					metrics.clientHedgeWinsTotal.Inc()
+				}
+				return res.resp, res.addr, nil
+			}
+			lastErr = res.err
+			// the replica that just failed hasn't been hedged against yet;
+			// retry against the next one now instead of waiting for the rest
+			// of the hedge window to elapse.
+			if !timer.Stop() {
+				<-timer.C
+			}
+			launchNext(next)
+			pending++
+			next++
+			timer.Reset(hedgeAfter)
+		case <-timer.C:
+			launchNext(next)
+			pending++
+			next++
+			timer.Reset(hedgeAfter)
+		}
+	}
+
+	return nil, addrs[0], lastErr
+}
+
+// filterChunkRefs issues req against a single bloom gateway replica.
+func (c *GatewayClient) filterChunkRefs(ctx context.Context, addr string, req *logproto.FilterChunkRefRequest) (*logproto.FilterChunkRefResponse, error) {
+	var resp *logproto.FilterChunkRefResponse
+	err := c.doForAddrs([]string{addr}, func(client logproto.BloomGatewayClient) error {
+		var err error
+		resp, err = client.FilterChunkRefs(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
 // doForAddrs sequetially calls the provided callback function fn for each
 // address in given slice addrs until the callback function does not return an
 // error.
@@ -390,7 +702,10 @@ func (c *GatewayClient) doForAddrs(addrs []string, fn func(logproto.BloomGateway
 }
 
 type addrWithGroups struct {
-	addr   string
+	// addrs holds the replicas owning this group of blocks, ordered by
+	// preference: addrs[0] is the primary (jumphash-selected) replica, and any
+	// remaining entries are only consulted for hedging/fallback.
+	addrs  []string
 	blocks []string
 	groups []*logproto.GroupedChunkRefs
 }
@@ -0,0 +1,72 @@
+package bloomgateway
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+func TestHedgedCall_SingleReplica(t *testing.T) {
+	metrics := newClientMetrics(prometheus.NewRegistry())
+
+	var called []string
+	resp, addr, err := hedgedCall([]string{"a"}, time.Second, metrics, func(addr string) (*logproto.FilterChunkRefResponse, error) {
+		called = append(called, addr)
+		return &logproto.FilterChunkRefResponse{}, nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "a", addr)
+	require.NotNil(t, resp)
+	require.Equal(t, []string{"a"}, called)
+}
+
+func TestHedgedCall_RetriesImmediatelyOnError(t *testing.T) {
+	metrics := newClientMetrics(prometheus.NewRegistry())
+
+	start := time.Now()
+	resp, addr, err := hedgedCall([]string{"a", "b"}, time.Second, metrics, func(addr string) (*logproto.FilterChunkRefResponse, error) {
+		if addr == "a" {
+			return nil, errors.New("a is down")
+		}
+		return &logproto.FilterChunkRefResponse{}, nil
+	})
+	elapsed := time.Since(start)
+
+	require.NoError(t, err)
+	require.Equal(t, "b", addr)
+	require.NotNil(t, resp)
+	// the failed primary should be retried right away, long before the
+	// one-second hedge window would otherwise have elapsed.
+	require.Less(t, elapsed, 500*time.Millisecond)
+}
+
+func TestHedgedCall_HedgeAfterZeroRacesAllReplicasImmediately(t *testing.T) {
+	metrics := newClientMetrics(prometheus.NewRegistry())
+
+	resp, addr, err := hedgedCall([]string{"a", "b", "c"}, 0, metrics, func(addr string) (*logproto.FilterChunkRefResponse, error) {
+		if addr != "c" {
+			return nil, errors.New("down")
+		}
+		return &logproto.FilterChunkRefResponse{}, nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "c", addr)
+	require.NotNil(t, resp)
+}
+
+func TestHedgedCall_AllReplicasFail(t *testing.T) {
+	metrics := newClientMetrics(prometheus.NewRegistry())
+
+	_, _, err := hedgedCall([]string{"a", "b"}, time.Millisecond, metrics, func(addr string) (*logproto.FilterChunkRefResponse, error) {
+		return nil, errors.New(addr + " is down")
+	})
+
+	require.Error(t, err)
+}
@@ -0,0 +1,12 @@
+package bloomgateway
+
+// Limits defines the per-tenant limits that the bloom gateway client
+// consults when handling a FilterChunks request.
+type Limits interface {
+	// BloomGatewayCacheDisableAllowed reports whether tenantID is allowed to
+	// request a forced cache refresh (CachingOptions.Disabled) on bloom
+	// gateway client requests. Tenants are restricted by default since an
+	// unbounded number of no-cache requests can be used to exhaust bloom
+	// gateway CPU.
+	BloomGatewayCacheDisableAllowed(tenantID string) bool
+}
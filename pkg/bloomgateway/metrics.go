@@ -0,0 +1,106 @@
+package bloomgateway
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	typeSuccess = "success"
+	typeError   = "error"
+)
+
+// clientMetrics holds the metrics emitted by the Bloom Gateway client.
+type clientMetrics struct {
+	// clients tracks the number of gRPC connections currently held open to
+	// bloom gateway instances.
+	clients prometheus.Gauge
+
+	// requestLatency tracks the latency of gRPC requests made to bloom
+	// gateway instances.
+	requestLatency *prometheus.HistogramVec
+
+	// clientRequests counts FilterChunkRefs/FilterChunkRefsStream calls by
+	// outcome (typeSuccess or typeError).
+	clientRequests *prometheus.CounterVec
+
+	// clientCacheBypassTotal counts FilterChunks calls that bypassed the
+	// client-side results cache via CachingOptions.Disabled.
+	clientCacheBypassTotal prometheus.Counter
+
+	// cacheLocalityScore observes how evenly jumphash distributed a
+	// request's fingerprint keyspace across instances; see FilterChunks for
+	// the exact formula.
+	cacheLocalityScore prometheus.Histogram
+
+	// clientHedgeLaunchesTotal counts secondary requests launched by
+	// filterChunkRefsHedged, either because the hedge timer fired or
+	// because the previous replica errored out before it could.
+	clientHedgeLaunchesTotal prometheus.Counter
+
+	// clientHedgeWinsTotal counts FilterChunkRefs calls that were ultimately
+	// served by a non-primary replica.
+	clientHedgeWinsTotal prometheus.Counter
+
+	// clientReplicaFallbacksTotal counts groups of blocks, across FilterChunks
+	// calls, for which every replica failed and the caller fell back to
+	// returning that group's chunks unfiltered.
+	clientReplicaFallbacksTotal prometheus.Counter
+}
+
+func newClientMetrics(registerer prometheus.Registerer) *clientMetrics {
+	return &clientMetrics{
+		clients: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Subsystem: "bloom_gateway_client",
+			Name:      "clients",
+			Help:      "The current number of bloom gateway clients.",
+		}),
+		requestLatency: promauto.With(registerer).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: constants.Loki,
+			Subsystem: "bloom_gateway_client",
+			Name:      "request_duration_seconds",
+			Help:      "Time (in seconds) spent serving requests to the bloom gateway.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "status_code"}),
+		clientRequests: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "bloom_gateway_client",
+			Name:      "requests_total",
+			Help:      "Total number of requests made to bloom gateway instances, by outcome.",
+		}, []string{"status"}),
+		clientCacheBypassTotal: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "bloom_gateway_client",
+			Name:      "cache_bypass_total",
+			Help:      "Total number of FilterChunks calls that bypassed the client-side results cache via CachingOptions.Disabled.",
+		}),
+		cacheLocalityScore: promauto.With(registerer).NewHistogram(prometheus.HistogramOpts{
+			Namespace: constants.Loki,
+			Subsystem: "bloom_gateway_client",
+			Name:      "cache_locality_score",
+			Help:      "Ratio of the fraction of the fingerprint keyspace a request covers to the fraction of instances required to serve it; converges to 1 for well-distributed requests.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 0.75, 0.9, 1, 1.1, 1.25, 1.5},
+		}),
+		clientHedgeLaunchesTotal: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "bloom_gateway_client",
+			Name:      "hedge_launches_total",
+			Help:      "Total number of secondary requests launched to hedge against a slow or failed replica.",
+		}),
+		clientHedgeWinsTotal: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "bloom_gateway_client",
+			Name:      "hedge_wins_total",
+			Help:      "Total number of requests ultimately served by a non-primary replica.",
+		}),
+		clientReplicaFallbacksTotal: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "bloom_gateway_client",
+			Name:      "replica_fallbacks_total",
+			Help:      "Total number of requests where every replica for a group of blocks failed and chunks were returned unfiltered.",
+		}),
+	}
+}
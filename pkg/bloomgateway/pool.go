@@ -0,0 +1,146 @@
+package bloomgateway
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	ringclient "github.com/grafana/dskit/ring/client"
+
+	"github.com/grafana/loki/v3/pkg/util/discovery"
+)
+
+// JumpHashClientPool wraps a pool of bloom gateway gRPC clients and uses jump
+// consistent hashing over the current set of known addresses to
+// deterministically pick which replicas own a given cache key (a block
+// reference). It keeps its own, periodically refreshed, sorted view of the
+// address list so that Addr/Addrs can be served without a DNS round trip on
+// every call.
+type JumpHashClientPool struct {
+	*ringclient.Pool
+
+	dnsProvider   *discovery.DNS
+	checkInterval time.Duration
+	logger        log.Logger
+
+	mu        sync.RWMutex
+	addresses []string
+
+	done chan struct{}
+}
+
+// NewJumpHashClientPool creates a JumpHashClientPool backed by pool for
+// client connections and dnsProvider for address discovery.
+func NewJumpHashClientPool(pool *ringclient.Pool, dnsProvider *discovery.DNS, checkInterval time.Duration, logger log.Logger) *JumpHashClientPool {
+	return &JumpHashClientPool{
+		Pool:          pool,
+		dnsProvider:   dnsProvider,
+		checkInterval: checkInterval,
+		logger:        logger,
+		done:          make(chan struct{}),
+	}
+}
+
+// Start begins periodically refreshing the address list used for jump
+// hashing. It must be called before Addr or Addrs is used.
+func (p *JumpHashClientPool) Start() {
+	p.refresh()
+
+	go func() {
+		ticker := time.NewTicker(p.checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.refresh()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the address refresh loop.
+func (p *JumpHashClientPool) Stop() {
+	close(p.done)
+}
+
+func (p *JumpHashClientPool) refresh() {
+	addrs := append([]string(nil), p.dnsProvider.Addresses()...)
+	sort.Strings(addrs)
+
+	p.mu.Lock()
+	p.addresses = addrs
+	p.mu.Unlock()
+}
+
+// Addrs returns the current, sorted set of known bloom gateway addresses.
+func (p *JumpHashClientPool) Addrs() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.addresses
+}
+
+// Addr returns the top-n addresses key jump-hashes to, ordered by
+// preference: addrs[0] is the primary owner of key, and the remaining
+// entries are the next-best replicas, picked by repeatedly removing the
+// previous winner from the candidate set and re-hashing. n is clamped to
+// the number of known addresses.
+func (p *JumpHashClientPool) Addr(key string, n int) ([]string, error) {
+	p.mu.RLock()
+	addrs := p.addresses
+	p.mu.RUnlock()
+
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses available for key %q", key)
+	}
+
+	if n > len(addrs) {
+		n = len(addrs)
+	}
+
+	candidates := append([]string(nil), addrs...)
+	hash := hashNew()
+	hash = hashAdd(hash, key)
+
+	result := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		idx := jumpHash(hash, len(candidates))
+		result = append(result, candidates[idx])
+		candidates = append(candidates[:idx], candidates[idx+1:]...)
+		hash = hashAdd(hash, result[len(result)-1])
+	}
+
+	return result, nil
+}
+
+// hashNew and hashAdd implement FNV-1a, used to turn a string key (and, for
+// successive replicas, the previously chosen address) into the uint64 jump
+// hash expects.
+func hashNew() uint64 {
+	return 14695981039346656037
+}
+
+func hashAdd(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+// jumpHash implements Lamping & Veach's "A Fast, Minimal Memory, Consistent
+// Hash Algorithm" (https://arxiv.org/abs/1406.2294): it maps key onto one of
+// numBuckets buckets such that, as numBuckets changes, only a minimal
+// fraction of keys move to a different bucket.
+func jumpHash(key uint64, numBuckets int) int32 {
+	var b, j int64 = -1, 0
+	for j < int64(numBuckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int32(b)
+}
@@ -0,0 +1,42 @@
+package bloomgateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJumpHashClientPool_Addr(t *testing.T) {
+	p := &JumpHashClientPool{addresses: []string{"a:1", "b:2", "c:3", "d:4", "e:5"}}
+
+	addrs, err := p.Addr("some-block", 3)
+	require.NoError(t, err)
+	require.Len(t, addrs, 3)
+
+	seen := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		require.False(t, seen[addr], "address %q returned more than once", addr)
+		seen[addr] = true
+		require.Contains(t, p.addresses, addr)
+	}
+
+	// same key, same replica count -> same ordered result every time.
+	again, err := p.Addr("some-block", 3)
+	require.NoError(t, err)
+	require.Equal(t, addrs, again)
+}
+
+func TestJumpHashClientPool_Addr_ClampsReplicaCountToAvailableAddresses(t *testing.T) {
+	p := &JumpHashClientPool{addresses: []string{"a:1", "b:2"}}
+
+	addrs, err := p.Addr("some-block", 5)
+	require.NoError(t, err)
+	require.Len(t, addrs, 2)
+}
+
+func TestJumpHashClientPool_Addr_NoAddresses(t *testing.T) {
+	p := &JumpHashClientPool{}
+
+	_, err := p.Addr("some-block", 1)
+	require.Error(t, err)
+}
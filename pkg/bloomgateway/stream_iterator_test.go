@@ -0,0 +1,69 @@
+package bloomgateway
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+func TestChunkRefStreamIterator_FallsBackWithoutDuplicating(t *testing.T) {
+	streamed := []*logproto.GroupedChunkRefs{
+		{Fingerprint: 1},
+		{Fingerprint: 2},
+	}
+	fallback := []*logproto.GroupedChunkRefs{
+		{Fingerprint: 1},
+		{Fingerprint: 2},
+		{Fingerprint: 3},
+		{Fingerprint: 4},
+	}
+
+	idx := 0
+	recv := func() (*logproto.GroupedChunkRefs, error) {
+		if idx < len(streamed) {
+			r := streamed[idx]
+			idx++
+			return r, nil
+		}
+		return nil, errors.New("stream broke")
+	}
+
+	count := atomic.NewInt64(0)
+	it := newChunkRefStreamIterator(log.NewNopLogger(), "addr", recv, fallback, count)
+
+	var got []uint64
+	for it.Next() {
+		got = append(got, it.At().Fingerprint)
+	}
+
+	require.NoError(t, it.Err())
+	require.Equal(t, []uint64{1, 2, 3, 4}, got)
+	require.EqualValues(t, 4, count.Load())
+}
+
+func TestChunkRefStreamIterator_FallsBackFromStart(t *testing.T) {
+	recv := func() (*logproto.GroupedChunkRefs, error) {
+		return nil, errors.New("stream never came up")
+	}
+	fallback := []*logproto.GroupedChunkRefs{
+		{Fingerprint: 1},
+		{Fingerprint: 2},
+	}
+
+	count := atomic.NewInt64(0)
+	it := newChunkRefStreamIterator(log.NewNopLogger(), "addr", recv, fallback, count)
+
+	var got []uint64
+	for it.Next() {
+		got = append(got, it.At().Fingerprint)
+	}
+
+	require.NoError(t, it.Err())
+	require.Equal(t, []uint64{1, 2}, got)
+	require.EqualValues(t, 2, count.Load())
+}